@@ -0,0 +1,27 @@
+package wordclouds
+
+// Box is an axis-aligned rectangle in canvas coordinates: a word's
+// bounding box, a mask region, or a query region tested against the
+// spatial hashmap. Top/Bottom and Left/Right are named for the value
+// placement computes them from (Top = y + height/2, Bottom = y - height/2),
+// not for screen orientation.
+type Box struct {
+	Top, Left, Right, Bottom float64
+}
+
+// x, y, w, h expose Box as a rect anchored at its lower-left corner, for
+// callers drawing it (e.g. DrawingContextI.DrawRectangle).
+func (b *Box) x() float64 { return b.Left }
+func (b *Box) y() float64 { return b.Bottom }
+func (b *Box) w() float64 { return b.Right - b.Left }
+func (b *Box) h() float64 { return b.Top - b.Bottom }
+
+// fits reports whether b lies entirely within a canvas of the given size.
+func (b *Box) fits(width float64, height float64) bool {
+	return b.Left >= 0 && b.Right <= width && b.Bottom >= 0 && b.Top <= height
+}
+
+// overlaps reports whether b and o share any area.
+func (b *Box) overlaps(o *Box) bool {
+	return b.Left < o.Right && b.Right > o.Left && b.Bottom < o.Top && b.Top > o.Bottom
+}
@@ -0,0 +1,31 @@
+package wordclouds
+
+import "math"
+
+// point is a 2D canvas coordinate.
+type point struct {
+	x, y float64
+}
+
+// circle precomputes n evenly spaced points on the circle of the given
+// radius centered at (cx, cy), so the placement search can walk an entire
+// radius without repeating the trig on every call.
+type circle struct {
+	pts []point
+}
+
+func newCircle(cx float64, cy float64, radius float64, n int) *circle {
+	pts := make([]point, n)
+	for i := 0; i < n; i++ {
+		theta := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = point{
+			x: cx + radius*math.Cos(theta),
+			y: cy + radius*math.Sin(theta),
+		}
+	}
+	return &circle{pts: pts}
+}
+
+func (c *circle) positions() []point {
+	return c.pts
+}
@@ -0,0 +1,238 @@
+// Command wcexercise is a long-running stress driver for the wordclouds
+// placement engine: it repeatedly builds a synthetic corpus, renders it, and
+// streams throughput and placement-quality metrics to stdout once a second.
+// It exists to give maintainers a repeatable way to evaluate the
+// multithreaded placement path (Options.Parallelism) and to spot
+// regressions when the sharded grid or drawing backends change.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/psykhi/wordclouds"
+)
+
+func main() {
+	duration := flag.Duration("duration", 10*time.Second, "how long to run the exerciser")
+	parallel := flag.Int("parallel", 1, "number of wordclouds to build and render concurrently")
+	canvas := flag.String("canvas", "1024x1024", "canvas size as WxH, e.g. 2048x1024")
+	corpusSize := flag.Int("corpus-size", 200, "number of words in each generated corpus")
+	fontFile := flag.String("font", "testdata/Go-Regular.ttf", "font file passed to wordclouds.Options.FontFile")
+	randomPlacement := flag.Bool("random-placement", false, "exercise the random placement search instead of the circular one")
+	parallelism := flag.Int("parallelism", 1, "wordclouds.Options.Parallelism: concurrent tail placement workers per draw (1 disables it)")
+	sequentialHead := flag.Int("sequential-head", 64, "wordclouds.Options.SequentialHeadCount: largest words placed one at a time before the concurrent tail")
+	cpuprofile := flag.String("cpuprofile", "", "write a CPU profile to this file")
+	memprofile := flag.String("memprofile", "", "write a heap profile to this file on exit")
+	flag.Parse()
+
+	width, height, err := parseCanvas(*canvas)
+	if err != nil {
+		log.Fatalf("-canvas: %v", err)
+	}
+
+	if *cpuprofile != "" {
+		f, err := os.Create(*cpuprofile)
+		if err != nil {
+			log.Fatalf("-cpuprofile: %v", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			log.Fatalf("-cpuprofile: %v", err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	m := &metrics{}
+	var wg sync.WaitGroup
+	for i := 0; i < *parallel; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			runWorker(ctx, id, width, height, *corpusSize, *fontFile, *randomPlacement, *parallelism, *sequentialHead, m)
+		}(i)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			m.report(start)
+		case <-done:
+			break loop
+		}
+	}
+	m.report(start)
+
+	if *memprofile != "" {
+		f, err := os.Create(*memprofile)
+		if err != nil {
+			log.Fatalf("-memprofile: %v", err)
+		}
+		defer f.Close()
+		runtime.GC()
+		if err := pprof.WriteHeapProfile(f); err != nil {
+			log.Fatalf("-memprofile: %v", err)
+		}
+	}
+}
+
+// runWorker repeatedly builds a fresh corpus and draws it until ctx is done,
+// feeding each draw's outcome into m.
+func runWorker(ctx context.Context, id int, width int, height int, corpusSize int, fontFile string, randomPlacement bool, parallelism int, sequentialHead int, m *metrics) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano() ^ int64(id)))
+	for ctx.Err() == nil {
+		data := buildCorpus(corpusSize, r)
+		wc := wordclouds.NewWordcloud(data, func(o *wordclouds.Options) {
+			o.Width = width
+			o.Height = height
+			o.FontFile = fontFile
+			o.RandomPlacement = randomPlacement
+			o.Parallelism = parallelism
+			o.SequentialHeadCount = sequentialHead
+		})
+		if _, err := wc.DrawContext(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("worker %d: draw error: %v", id, err)
+		}
+		m.recordDraw(data, wc.Stats())
+	}
+}
+
+// buildCorpus generates a corpus of n random words with random counts,
+// sorted the way NewWordcloud requires (descending by count).
+func buildCorpus(n int, r *rand.Rand) *wordclouds.WordDataCoArrays {
+	data := wordclouds.NewWordDataCoArrays(n)
+	for i := 0; i < n; i++ {
+		count := r.Intn(1000) + 1
+		data.Add(fmt.Sprintf("word-%d-%d", i, count), count, uint16(i%32))
+	}
+	data.SortByCount()
+	return data
+}
+
+func parseCanvas(s string) (width int, height int, err error) {
+	parts := strings.SplitN(strings.ToLower(s), "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected WxH, got %q", s)
+	}
+	width, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid width in %q: %w", s, err)
+	}
+	height, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid height in %q: %w", s, err)
+	}
+	return width, height, nil
+}
+
+// metrics accumulates throughput and placement-quality counters across all
+// workers; every field is updated from multiple goroutines via atomics.
+type metrics struct {
+	draws            atomic.Int64
+	wordsPlaced      atomic.Int64
+	wordsTotal       atomic.Int64
+	nextPosCalls     atomic.Int64
+	nextPosNanos     atomic.Int64
+	nextPosHistogram [len(wordclouds.NextPosLatencyBucketsNanos) + 1]atomic.Int64
+	randomCalls      atomic.Int64
+	randomTries      atomic.Int64
+	peakOccupancy    atomic.Int64
+}
+
+func (m *metrics) recordDraw(data *wordclouds.WordDataCoArrays, stats wordclouds.StatsSnapshot) {
+	placed := int64(data.PlacedCount())
+	m.draws.Add(1)
+	m.wordsPlaced.Add(placed)
+	m.wordsTotal.Add(int64(data.Length()))
+	m.nextPosCalls.Add(stats.NextPosCalls)
+	m.nextPosNanos.Add(int64(stats.AvgNextPosNanos * float64(stats.NextPosCalls)))
+	for i, count := range stats.NextPosLatencyHistogram {
+		m.nextPosHistogram[i].Add(count)
+	}
+	m.randomCalls.Add(stats.RandomCalls)
+	m.randomTries.Add(int64(stats.AvgRandomTries * float64(stats.RandomCalls)))
+
+	for {
+		cur := m.peakOccupancy.Load()
+		if placed <= cur || m.peakOccupancy.CompareAndSwap(cur, placed) {
+			break
+		}
+	}
+}
+
+// report prints one line of current throughput and quality metrics. Grid
+// occupancy isn't exposed by the spatial hashmap itself, so peak_occupancy
+// is approximated as the most words ever committed to the grid in a single
+// draw.
+func (m *metrics) report(start time.Time) {
+	elapsed := time.Since(start).Seconds()
+	placed := m.wordsPlaced.Load()
+	total := m.wordsTotal.Load()
+
+	var placedRatio float64
+	if total > 0 {
+		placedRatio = float64(placed) / float64(total)
+	}
+
+	var avgNextPos time.Duration
+	if calls := m.nextPosCalls.Load(); calls > 0 {
+		avgNextPos = time.Duration(m.nextPosNanos.Load() / calls)
+	}
+
+	var avgRandomTries float64
+	if calls := m.randomCalls.Load(); calls > 0 {
+		avgRandomTries = float64(m.randomTries.Load()) / float64(calls)
+	}
+
+	var wordsPerSec float64
+	if elapsed > 0 {
+		wordsPerSec = float64(placed) / elapsed
+	}
+
+	fmt.Printf(
+		"t=%5.0fs draws=%-6d words/s=%-9.1f placed_ratio=%-6.3f avg_next_pos=%-10s avg_random_tries=%-8.1f peak_occupancy=%d\n",
+		elapsed, m.draws.Load(), wordsPerSec, placedRatio, avgNextPos, avgRandomTries, m.peakOccupancy.Load(),
+	)
+	fmt.Printf("  next_pos histogram: %s\n", m.nextPosHistogramString())
+}
+
+// nextPosHistogramString renders the accumulated nextPos latency histogram
+// as bucket_label=count pairs, e.g. "<1us=12 <10us=340 ... >=1s=0".
+func (m *metrics) nextPosHistogramString() string {
+	var b strings.Builder
+	for i := range m.nextPosHistogram {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		count := m.nextPosHistogram[i].Load()
+		if i < len(wordclouds.NextPosLatencyBucketsNanos) {
+			fmt.Fprintf(&b, "<%s=%d", time.Duration(wordclouds.NextPosLatencyBucketsNanos[i]), count)
+		} else {
+			fmt.Fprintf(&b, ">=%s=%d", time.Duration(wordclouds.NextPosLatencyBucketsNanos[i-1]), count)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,327 @@
+package wordclouds
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// stripedGrid lets multiple goroutines place words concurrently without two
+// of them ever mutating the same *spatialHashMap at once. spatialHashMap is
+// presumed Go-map-backed internally (nextPos's cleanup path already waits
+// for every worker to exit before touching the grid again specifically to
+// avoid concurrent map access), so wrapping a single shared instance in
+// per-region mutexes is not enough: two goroutines writing to logically
+// disjoint cells would still be mutating the same underlying map value at
+// the same time, which Go map writes do not tolerate. Instead, each stripe
+// owns its own independent spatialHashMap instance, so concurrent writes to
+// different stripes land on genuinely different maps. base is the grid as
+// it stood before concurrent placement began (mask boxes plus whatever the
+// sequential head placed); it is never written to again while workers run,
+// so reading it concurrently is safe, and collision checks consult it
+// alongside the stripe(s) a candidate box touches.
+type stripedGrid struct {
+	base     *spatialHashMap
+	cellSize float64
+	shards   []*spatialHashMap
+	mus      []sync.Mutex
+}
+
+func newStripedGrid(base *spatialHashMap, width float64, height float64, cellSize float64, stripes int) *stripedGrid {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	n := 1
+	for n < stripes {
+		n <<= 1
+	}
+	shards := make([]*spatialHashMap, n)
+	for i := range shards {
+		shards[i] = newSpatialHashMap(width, height, int(cellSize))
+	}
+	return &stripedGrid{base: base, cellSize: cellSize, shards: shards, mus: make([]sync.Mutex, n)}
+}
+
+func (s *stripedGrid) cell(v float64) int {
+	return int(math.Floor(v / s.cellSize))
+}
+
+func (s *stripedGrid) stripeOf(cellX int, cellY int) int {
+	return (cellX ^ cellY) & (len(s.mus) - 1)
+}
+
+// touchedStripes returns the sorted, de-duplicated stripes b's cells fall
+// into. Locking them in sorted order everywhere prevents lock-order
+// deadlocks between two boxes that both touch a shared stripe.
+func (s *stripedGrid) touchedStripes(b *Box) []int {
+	x0, x1 := s.cell(b.Left), s.cell(b.Right)
+	y0, y1 := s.cell(b.Bottom), s.cell(b.Top)
+	seen := make(map[int]struct{}, (x1-x0+1)*(y1-y0+1))
+	for cx := x0; cx <= x1; cx++ {
+		for cy := y0; cy <= y1; cy++ {
+			seen[s.stripeOf(cx, cy)] = struct{}{}
+		}
+	}
+	ids := make([]int, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (s *stripedGrid) lock(ids []int) {
+	for _, id := range ids {
+		s.mus[id].Lock()
+	}
+}
+
+func (s *stripedGrid) unlock(ids []int) {
+	for _, id := range ids {
+		s.mus[id].Unlock()
+	}
+}
+
+// TestCollision checks b against the immutable base grid and, while holding
+// every stripe b touches, against the stripes' own shards -- so the read
+// can't race with a concurrent TryCommit on an overlapping box.
+func (s *stripedGrid) TestCollision(b *Box, overlaps func(a *Box, b *Box) bool) bool {
+	if colliding, _ := s.base.TestCollision(b, overlaps); colliding {
+		return true
+	}
+	ids := s.touchedStripes(b)
+	s.lock(ids)
+	defer s.unlock(ids)
+	for _, id := range ids {
+		if colliding, _ := s.shards[id].TestCollision(b, overlaps); colliding {
+			return true
+		}
+	}
+	return false
+}
+
+// TryCommit re-checks b for collisions against the base grid and every
+// shard it touches and, if it still fits, adds it to each of those shards --
+// atomically with respect to every other stripedGrid call touching the same
+// stripes. It reports whether the box was committed; false means another
+// worker claimed an overlapping box between the caller's search and this
+// call, and the caller should search for a new position and retry.
+func (s *stripedGrid) TryCommit(b *Box, overlaps func(a *Box, b *Box) bool) bool {
+	if colliding, _ := s.base.TestCollision(b, overlaps); colliding {
+		return false
+	}
+	ids := s.touchedStripes(b)
+	s.lock(ids)
+	defer s.unlock(ids)
+	for _, id := range ids {
+		if colliding, _ := s.shards[id].TestCollision(b, overlaps); colliding {
+			return false
+		}
+	}
+	for _, id := range ids {
+		s.shards[id].Add(b)
+	}
+	return true
+}
+
+func boxesOverlap(a *Box, b *Box) bool {
+	return a.overlaps(b)
+}
+
+// drawConcurrent places sortedWordList[start:end) across w.opts.Parallelism
+// workers, each claiming boxes through a stripedGrid built over w.grid.
+// Placement of a single word is still searched sequentially (one goroutine
+// per word, not the per-radius worker pool nextPos uses), since the
+// concurrency here comes from running many words at once rather than from
+// parallelizing a single word's circle search.
+func (w *Wordcloud) drawConcurrent(ctx context.Context, start int, end int) error {
+	parallelism := w.opts.Parallelism
+	if parallelism > runtime.NumCPU() {
+		parallelism = runtime.NumCPU()
+	}
+	sg := newStripedGrid(w.grid, w.width, w.height, w.height/10, parallelism*4)
+
+	var dcMu sync.Mutex // the DrawingContextI is not safe for concurrent use
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	idxCh := make(chan int)
+
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range idxCh {
+				if err := w.placeConcurrent(ctx, sg, &dcMu, idx); err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+
+feed:
+	for i := start; i < end; i++ {
+		select {
+		case <-ctx.Done():
+			break feed
+		case idxCh <- i:
+		}
+	}
+	close(idxCh)
+	wg.Wait()
+
+	// Every worker has exited, so the shards are no longer written to; fold
+	// them into w.grid so it reflects every word the concurrent tail placed,
+	// the same way the sequential path adds directly to w.grid.
+	for _, shard := range sg.shards {
+		for _, b := range shard.Boxes() {
+			w.grid.Add(b)
+		}
+	}
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// placeConcurrent is the concurrent-safe counterpart to Place: the position
+// search and the grid commit are split so that a collision discovered
+// between them (another worker took the spot first) causes a retry instead
+// of corrupting the grid. Unlike Place, tall words do not get their bounding
+// box refined against the rendered pixels, since that optimization assumes
+// no other word can be added to the grid while it runs; words always
+// reserve their full (padded) box here.
+func (w *Wordcloud) placeConcurrent(ctx context.Context, sg *stripedGrid, dcMu *sync.Mutex, idx int) error {
+	data := w.sortedWordList
+	word := data.Word[idx]
+	col := w.opts.Colors[int(data.ColorIndex[idx])%len(w.opts.Colors)]
+
+	const maxRetries = 8
+	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		dcMu.Lock()
+		w.dc.SetColor(col)
+		w.setFont(float64(data.FontSize[idx]))
+		width, height := w.dc.MeasureString(word)
+		dcMu.Unlock()
+		width += 5
+		height += 5
+
+		x, y, space, err := w.nextPosConcurrent(ctx, sg, width, height)
+		if err != nil {
+			return err
+		}
+		if !space {
+			data.Pos[idx] = notPlacedPos
+			data.Rect[idx] = 0.0
+			return nil
+		}
+
+		box := &Box{
+			y + height/2 + 0.3*height,
+			x - width/2,
+			x + width/2,
+			math.Max(y-height/2, 0),
+		}
+		if !sg.TryCommit(box, boxesOverlap) {
+			continue
+		}
+
+		const ax = 0.5
+		const ay = 0.5
+		dcMu.Lock()
+		w.dc.SetColor(col)
+		w.setFont(float64(data.FontSize[idx]))
+		w.dc.DrawStringAnchored(word, x, y, ax, ay)
+		if w.opts.Debug {
+			w.dc.DrawRectangle(box.x(), box.y(), box.w(), box.h())
+			w.dc.Stroke()
+		}
+		dcMu.Unlock()
+		data.Pos[idx] = complex(float32(x-ax*(width-5)), float32(y-ay*(height-5)))
+		data.Rect[idx] = complex(float32(width-5), float32(height-5))
+		return nil
+	}
+
+	data.Pos[idx] = notPlacedPos
+	data.Rect[idx] = 0.0
+	return nil
+}
+
+// nextPosConcurrent is nextPos's single-goroutine-per-word counterpart: it
+// walks the circles in order itself instead of fanning out across a worker
+// pool, using sg so the search is safe alongside other words' workers.
+func (w *Wordcloud) nextPosConcurrent(ctx context.Context, sg *stripedGrid, width float64, height float64) (x float64, y float64, space bool, err error) {
+	start := time.Now()
+	defer func() {
+		w.stats.recordNextPos(time.Since(start))
+	}()
+	if w.randomPlacement {
+		return w.nextRandomConcurrent(ctx, sg, width, height)
+	}
+
+	var box Box
+	for _, r := range w.radii {
+		if e := ctx.Err(); e != nil {
+			return 0, 0, false, e
+		}
+		for i, p := range w.circles[r].positions() {
+			if i&0xff == 0 {
+				select {
+				case <-ctx.Done():
+					return 0, 0, false, ctx.Err()
+				default:
+				}
+			}
+			box.Top = p.y + height/2
+			box.Left = p.x - width/2
+			box.Right = p.x + width/2
+			box.Bottom = p.y - height/2
+			if !box.fits(w.width, w.height) {
+				continue
+			}
+			if !sg.TestCollision(&box, boxesOverlap) {
+				return p.x, p.y, true, nil
+			}
+		}
+	}
+	return 0, 0, false, nil
+}
+
+func (w *Wordcloud) nextRandomConcurrent(ctx context.Context, sg *stripedGrid, width float64, height float64) (x float64, y float64, space bool, err error) {
+	tries := 0
+	defer func() {
+		w.stats.RandomCalls.Add(1)
+		w.stats.RandomTries.Add(int64(tries))
+	}()
+	var box Box
+	for ; tries < 5000000; tries++ {
+		if tries&0x3ff == 0 {
+			select {
+			case <-ctx.Done():
+				return 0, 0, false, ctx.Err()
+			default:
+			}
+		}
+		x = float64(w.randIntn(w.dc.Width()))
+		y = float64(w.randIntn(w.dc.Height()))
+		box.Top = y + height/2
+		box.Left = x - width/2
+		box.Right = x + width/2
+		box.Bottom = y - height/2
+		if !box.fits(w.width, w.height) {
+			continue
+		}
+		if !sg.TestCollision(&box, boxesOverlap) {
+			return x, y, true, nil
+		}
+	}
+	return 0, 0, false, nil
+}
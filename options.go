@@ -0,0 +1,64 @@
+package wordclouds
+
+import (
+	"image/color"
+	"math/rand"
+)
+
+// Option configures a Wordcloud at construction time; see NewWordcloud.
+// Callers write their own closures over Options (NewWordcloud starts from
+// defaultOptions, so only the fields that need to change have to be set).
+type Option func(*Options)
+
+// Options holds every configurable aspect of a Wordcloud's layout and
+// rendering.
+type Options struct {
+	Width  int
+	Height int
+
+	FontFile     string
+	FontMaxSize  int
+	FontMinSize  int
+	SizeFunction func(wordWeight float64) float64
+
+	Colors          []color.Color
+	BackgroundColor color.Color
+
+	// Mask reserves regions of the canvas (e.g. a logo) so no word is
+	// placed over them; Debug additionally outlines placed and masked
+	// boxes on the canvas.
+	Mask  []*Box
+	Debug bool
+
+	// RandomPlacement exercises nextRandom's uniform random search instead
+	// of the default circular search outward from the canvas center.
+	RandomPlacement bool
+
+	// DrawingContext overrides the default raster backend, e.g. with
+	// NewSVGDrawingContext for vector output.
+	DrawingContext DrawingContextI
+
+	// Rand seeds placement's random search; nil falls back to a
+	// time-seeded source. Inject a fixed seed for reproducible layouts.
+	Rand *rand.Rand
+
+	// Parallelism, when greater than 1, places SequentialHeadCount words
+	// one at a time and then the remaining tail concurrently; see
+	// Wordcloud.DrawContext. SequentialHeadCount defaults to a non-zero
+	// value (see defaultOptions) so turning on Parallelism alone still
+	// keeps the largest, canvas-dominating words on the sequential path.
+	Parallelism         int
+	SequentialHeadCount int
+}
+
+var defaultOptions = Options{
+	Width:               2048,
+	Height:              1024,
+	FontMaxSize:         500,
+	FontMinSize:         10,
+	SizeFunction:        func(wordWeight float64) float64 { return wordWeight },
+	Colors:              []color.Color{color.Black},
+	BackgroundColor:     color.White,
+	Parallelism:         1,
+	SequentialHeadCount: 64,
+}
@@ -0,0 +1,31 @@
+package wordclouds
+
+import (
+	"github.com/fogleman/gg"
+	"golang.org/x/image/font"
+)
+
+// RasterDrawingContext is the default DrawingContextI backend. It renders
+// directly to an in-memory raster image via github.com/fogleman/gg and is
+// what NewWordcloud uses unless an Options.DrawingContext is supplied.
+type RasterDrawingContext struct {
+	*gg.Context
+}
+
+// NewRasterDrawingContext creates a raster backend of the given size.
+func NewRasterDrawingContext(width, height int) *RasterDrawingContext {
+	return &RasterDrawingContext{Context: gg.NewContext(width, height)}
+}
+
+// LoadFontFace loads the font at path at the given point size. The raster
+// backend delegates straight to gg.LoadFontFace.
+func (c *RasterDrawingContext) LoadFontFace(path string, size float64) (font.Face, error) {
+	return gg.LoadFontFace(path, size)
+}
+
+// SetFontFace shadows gg.Context's embedded method so RasterDrawingContext
+// satisfies FontDrawingContextI; the raster backend has no use for size
+// since face already encodes it.
+func (c *RasterDrawingContext) SetFontFace(face font.Face, size float64) {
+	c.Context.SetFontFace(face)
+}
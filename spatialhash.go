@@ -0,0 +1,85 @@
+package wordclouds
+
+import "math"
+
+// spatialHashMap buckets Boxes into fixed-size grid cells so a collision
+// test only has to examine boxes near the candidate instead of every box
+// placed so far. It is backed by a plain Go map keyed by cell, so -- like
+// any Go map -- concurrent reads are safe but a concurrent write, even to
+// a logically disjoint cell, is not; concurrent callers must synchronize
+// externally (see stripedGrid in concurrent.go).
+type spatialHashMap struct {
+	cellSize float64
+	cells    map[int64][]*Box
+}
+
+func newSpatialHashMap(width float64, height float64, cellSize int) *spatialHashMap {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	return &spatialHashMap{
+		cellSize: float64(cellSize),
+		cells:    make(map[int64][]*Box),
+	}
+}
+
+func (s *spatialHashMap) cell(v float64) int {
+	return int(math.Floor(v / s.cellSize))
+}
+
+func (s *spatialHashMap) cellKey(cx int, cy int) int64 {
+	return int64(cx)<<32 | int64(uint32(cy))
+}
+
+// Add inserts b into every cell it overlaps.
+func (s *spatialHashMap) Add(b *Box) {
+	x0, x1 := s.cell(b.Left), s.cell(b.Right)
+	y0, y1 := s.cell(b.Bottom), s.cell(b.Top)
+	for cx := x0; cx <= x1; cx++ {
+		for cy := y0; cy <= y1; cy++ {
+			key := s.cellKey(cx, cy)
+			s.cells[key] = append(s.cells[key], b)
+		}
+	}
+}
+
+// Boxes returns every distinct Box added to s, de-duplicated the same way
+// TestCollision de-duplicates candidates spanning multiple cells.
+func (s *spatialHashMap) Boxes() []*Box {
+	seen := make(map[*Box]struct{})
+	boxes := make([]*Box, 0, len(s.cells))
+	for _, cell := range s.cells {
+		for _, b := range cell {
+			if _, dup := seen[b]; dup {
+				continue
+			}
+			seen[b] = struct{}{}
+			boxes = append(boxes, b)
+		}
+	}
+	return boxes
+}
+
+// TestCollision reports whether b overlaps any box already added to a cell
+// b touches -- as judged by overlaps -- and returns the first such box, if
+// any. A box spanning multiple cells can be examined more than once, so
+// candidates are de-duplicated before being passed to overlaps.
+func (s *spatialHashMap) TestCollision(b *Box, overlaps func(a *Box, b *Box) bool) (bool, *Box) {
+	x0, x1 := s.cell(b.Left), s.cell(b.Right)
+	y0, y1 := s.cell(b.Bottom), s.cell(b.Top)
+	seen := make(map[*Box]struct{})
+	for cx := x0; cx <= x1; cx++ {
+		for cy := y0; cy <= y1; cy++ {
+			for _, o := range s.cells[s.cellKey(cx, cy)] {
+				if _, dup := seen[o]; dup {
+					continue
+				}
+				seen[o] = struct{}{}
+				if overlaps(b, o) {
+					return true, o
+				}
+			}
+		}
+	}
+	return false, nil
+}
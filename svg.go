@@ -0,0 +1,180 @@
+package wordclouds
+
+import (
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"strings"
+
+	"golang.org/x/image/font"
+)
+
+// SVGDrawingContext is a DrawingContextI backend that records draws as SVG
+// markup instead of rasterizing them, so a Wordcloud can be exported as a
+// lossless, zoomable vector image. It keeps an internal RasterDrawingContext
+// alongside the SVG buffer, since font metrics and Image() rasterization
+// still need an actual rendered font face.
+type SVGDrawingContext struct {
+	width, height int
+	raster        *RasterDrawingContext
+	elements      []string
+	color         color.Color
+	fontSize      float64
+	pendingRect   svgRect
+}
+
+type svgRect struct {
+	x, y, w, h float64
+	valid      bool
+}
+
+// NewSVGDrawingContext creates an SVG backend of the given size.
+func NewSVGDrawingContext(width, height int) *SVGDrawingContext {
+	return &SVGDrawingContext{
+		width:  width,
+		height: height,
+		raster: NewRasterDrawingContext(width, height),
+		color:  color.Black,
+	}
+}
+
+func (s *SVGDrawingContext) SetColor(col color.Color) {
+	s.color = col
+	s.raster.SetColor(col)
+}
+
+func (s *SVGDrawingContext) SetRGB(r, g, b float64) {
+	s.raster.SetRGB(r, g, b)
+	s.SetColor(color.RGBA{
+		R: clamp255(r),
+		G: clamp255(g),
+		B: clamp255(b),
+		A: 255,
+	})
+}
+
+func clamp255(v float64) uint8 {
+	if v <= 0 {
+		return 0
+	}
+	if v >= 1 {
+		return 255
+	}
+	return uint8(v*255 + 0.5)
+}
+
+func (s *SVGDrawingContext) MeasureString(str string) (w, h float64) {
+	return s.raster.MeasureString(str)
+}
+
+// SetFontFace records size as the active font size for emitted <text>
+// elements, on every call -- including a font-cache hit in setFont, where
+// LoadFontFace is never invoked -- so font-size attributes track the word
+// actually being drawn rather than whichever size was last freshly loaded.
+func (s *SVGDrawingContext) SetFontFace(face font.Face, size float64) {
+	s.raster.SetFontFace(face, size)
+	s.fontSize = size
+}
+
+// LoadFontFace loads the font via the backing raster context so that
+// MeasureString stays accurate.
+func (s *SVGDrawingContext) LoadFontFace(path string, size float64) (font.Face, error) {
+	return s.raster.LoadFontFace(path, size)
+}
+
+func (s *SVGDrawingContext) Width() int  { return s.width }
+func (s *SVGDrawingContext) Height() int { return s.height }
+
+// DrawStringAnchored emits a <text> element at the anchor point, using the
+// same baseline math as gg.Context.DrawStringAnchored (the anchor point is
+// x - ax*w, y + ay*h) so SVG and raster output line up.
+func (s *SVGDrawingContext) DrawStringAnchored(str string, x, y, ax, ay float64) {
+	w, h := s.MeasureString(str)
+	s.raster.DrawStringAnchored(str, x, y, ax, ay)
+
+	bx := x - ax*w
+	by := y + ay*h
+	s.elements = append(s.elements, fmt.Sprintf(
+		`<text x="%.2f" y="%.2f" font-size="%.2f" fill="%s"%s>%s</text>`,
+		bx, by, s.fontSize, colorToHex(s.color), colorOpacityAttr("fill-opacity", s.color), html.EscapeString(str),
+	))
+}
+
+// DrawRectangle stages a rectangle; it is only emitted once Stroke is
+// called, mirroring gg's path-then-stroke drawing model.
+func (s *SVGDrawingContext) DrawRectangle(x, y, w, h float64) {
+	s.raster.DrawRectangle(x, y, w, h)
+	s.pendingRect = svgRect{x: x, y: y, w: w, h: h, valid: true}
+}
+
+func (s *SVGDrawingContext) Stroke() {
+	s.raster.Stroke()
+	if !s.pendingRect.valid {
+		return
+	}
+	r := s.pendingRect
+	s.pendingRect.valid = false
+	s.elements = append(s.elements, fmt.Sprintf(
+		`<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="none" stroke="%s"%s/>`,
+		r.x, r.y, r.w, r.h, colorToHex(s.color), colorOpacityAttr("stroke-opacity", s.color),
+	))
+}
+
+// Clear resets the SVG buffer to a single background rectangle filled with
+// the current color, matching the DrawingContextI.Clear contract used by
+// NewWordcloud to paint Options.BackgroundColor.
+func (s *SVGDrawingContext) Clear() {
+	s.raster.Clear()
+	s.elements = s.elements[:0]
+	s.elements = append(s.elements, fmt.Sprintf(
+		`<rect x="0" y="0" width="%d" height="%d" fill="%s"%s/>`,
+		s.width, s.height, colorToHex(s.color), colorOpacityAttr("fill-opacity", s.color),
+	))
+}
+
+// Image rasterizes the wordcloud via the backing gg context, for callers
+// that still need a pixel image alongside (or instead of) the SVG.
+func (s *SVGDrawingContext) Image() image.Image {
+	return s.raster.Image()
+}
+
+// SVG renders the accumulated draws as a standalone SVG document.
+func (s *SVGDrawingContext) SVG() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n",
+		s.width, s.height, s.width, s.height)
+	for _, e := range s.elements {
+		b.WriteString(e)
+		b.WriteByte('\n')
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+// colorToHex renders c's straight (non-premultiplied) RGB as "#rrggbb".
+// color.Color.RGBA returns alpha-premultiplied components, so they are
+// un-premultiplied first -- otherwise a translucent color would come out
+// darkened here and then darkened again by the fill-opacity/stroke-opacity
+// attribute colorOpacityAttr adds alongside it.
+func colorToHex(c color.Color) string {
+	r, g, b, a := c.RGBA()
+	if a == 0 {
+		return "#000000"
+	}
+	r = r * 0xffff / a
+	g = g * 0xffff / a
+	b = b * 0xffff / a
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// colorOpacityAttr returns a leading-space attr="0.xxx" attribute for c's
+// alpha, or "" when c is fully opaque, so SVG output matches translucent
+// Options.Colors/mask colors instead of silently rendering them opaque.
+func colorOpacityAttr(attr string, c color.Color) string {
+	_, _, _, a := c.RGBA()
+	if a >= 0xffff {
+		return ""
+	}
+	return fmt.Sprintf(` %s="%.3f"`, attr, float64(a)/0xffff)
+}
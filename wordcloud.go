@@ -1,6 +1,7 @@
 package wordclouds
 
 import (
+	"context"
 	"fmt"
 	"image"
 	"image/color"
@@ -9,24 +10,32 @@ import (
 	"math/rand"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/fogleman/gg"
 	"github.com/stergiotis/boxer/public/containers/co"
-	"github.com/stergiotis/boxer/public/math32"
+	"github.com/stergiotis/boxer/public/math/math32"
 	"golang.org/x/exp/slices"
 	"golang.org/x/image/font"
 )
 
 type FontDrawingContextI interface {
-	SetFontFace(face font.Face)
+	// SetFontFace activates face for subsequent draws. size is the point size
+	// face was loaded at; it is passed on every call (not just on a fresh
+	// LoadFontFace) so a backend that needs to know the active size -- e.g.
+	// to label output -- stays correct across a font cache hit.
+	SetFontFace(face font.Face, size float64)
+	// LoadFontFace loads the font at path at the given point size. Backends
+	// own font loading themselves (instead of Wordcloud assuming an on-disk
+	// gg font file), so e.g. a vector backend can load it purely for metrics.
+	LoadFontFace(path string, size float64) (font.Face, error)
 }
 
 type VectorDrawingContextI interface {
 	SetColor(col color.Color)
 	MeasureString(s string) (w, h float64)
 	// DrawStringAnchored draws the specified text at the specified anchor point.
-	// The anchor point is x - w * ax, y - h * ay, where w, h is the size of the
+	// The anchor point is x - ax * w, y + ay * h, where w, h is the size of the
 	// text. Use ax=0.5, ay=0.5 to center the text at the specified point.
 	DrawStringAnchored(s string, x, y, ax, ay float64)
 	Width() int
@@ -51,9 +60,8 @@ type HookFunc func(word string, x, y, w, h float64, col color.Color, size float6
 type Wordcloud struct {
 	sortedWordList *WordDataCoArrays
 
-	grid *spatialHashMap
-	dc   *gg.Context
-	//dc              DrawingContextI
+	grid            *spatialHashMap
+	dc              DrawingContextI
 	randomPlacement bool
 	width           float64
 	height          float64
@@ -62,7 +70,86 @@ type Wordcloud struct {
 	fonts           map[float64]font.Face
 	radii           []float64
 	hook            HookFunc
+	rand            *rand.Rand
+	randMu          sync.Mutex
+	stats           Stats
 }
+
+// NextPosLatencyBucketsNanos are the upper bounds (exclusive, nanoseconds)
+// of the Stats.NextPosLatencyBuckets histogram; the last bucket catches
+// everything at or above NextPosLatencyBucketsNanos[len-1].
+var NextPosLatencyBucketsNanos = [...]int64{
+	1_000,       // 1us
+	10_000,      // 10us
+	100_000,     // 100us
+	1_000_000,   // 1ms
+	10_000_000,  // 10ms
+	100_000_000, // 100ms
+	1_000_000_000,
+}
+
+// Stats accumulates placement metrics across a Wordcloud's lifetime. It is
+// safe for concurrent use; read a point-in-time view with Wordcloud.Stats.
+type Stats struct {
+	NextPosCalls          atomic.Int64
+	NextPosNanos          atomic.Int64
+	NextPosLatencyBuckets [len(NextPosLatencyBucketsNanos) + 1]atomic.Int64
+	RandomCalls           atomic.Int64
+	RandomTries           atomic.Int64
+}
+
+// recordNextPos accounts a single nextPos/nextPosConcurrent call of the
+// given latency: the running sum used for the average, and the histogram
+// bucket it falls into.
+func (s *Stats) recordNextPos(d time.Duration) {
+	s.NextPosCalls.Add(1)
+	s.NextPosNanos.Add(int64(d))
+	bucket := len(NextPosLatencyBucketsNanos)
+	for i, upperBound := range NextPosLatencyBucketsNanos {
+		if int64(d) < upperBound {
+			bucket = i
+			break
+		}
+	}
+	s.NextPosLatencyBuckets[bucket].Add(1)
+}
+
+// StatsSnapshot is a readable, averaged view of Stats at a point in time.
+type StatsSnapshot struct {
+	NextPosCalls    int64
+	AvgNextPosNanos float64
+	// NextPosLatencyHistogram holds one count per bucket in
+	// NextPosLatencyBucketsNanos, plus a trailing overflow bucket; see that
+	// var's doc comment for bucket boundaries.
+	NextPosLatencyHistogram [len(NextPosLatencyBucketsNanos) + 1]int64
+	RandomCalls             int64
+	AvgRandomTries          float64
+}
+
+// Stats returns a snapshot of the placement metrics accumulated so far,
+// e.g. for a benchmark harness to report throughput and search cost.
+func (w *Wordcloud) Stats() StatsSnapshot {
+	nextPosCalls := w.stats.NextPosCalls.Load()
+	randomCalls := w.stats.RandomCalls.Load()
+	snap := StatsSnapshot{
+		NextPosCalls:    nextPosCalls,
+		AvgNextPosNanos: avgRatio(w.stats.NextPosNanos.Load(), nextPosCalls),
+		RandomCalls:     randomCalls,
+		AvgRandomTries:  avgRatio(w.stats.RandomTries.Load(), randomCalls),
+	}
+	for i := range w.stats.NextPosLatencyBuckets {
+		snap.NextPosLatencyHistogram[i] = w.stats.NextPosLatencyBuckets[i].Load()
+	}
+	return snap
+}
+
+func avgRatio(total int64, count int64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
 type WordDataCoArrays struct {
 	Word       []string
 	Count      []int
@@ -171,13 +258,23 @@ func NewWordcloud(sortedWordList *WordDataCoArrays, options ...Option) *Wordclou
 		m = count
 	}
 
-	//var dc DrawingContextI
-	var dc *gg.Context
-	dc = gg.NewContext(opts.Width, opts.Height)
+	var dc DrawingContextI
+	if opts.DrawingContext != nil {
+		dc = opts.DrawingContext
+		if dc.Width() != opts.Width || dc.Height() != opts.Height {
+			panic(fmt.Sprintf("wordclouds: supplied DrawingContext is %dx%d but Options.Width/Height is %dx%d; the placement grid, circle search and bounds checks all need a single size", dc.Width(), dc.Height(), opts.Width, opts.Height))
+		}
+	} else {
+		dc = NewRasterDrawingContext(opts.Width, opts.Height)
+	}
+	// dc is now the single source of truth for canvas size: nextRandom and
+	// nextRandomConcurrent clamp candidate coordinates to w.dc.Width()/Height(),
+	// so every other size-derived structure below must agree with it.
+	width, height := dc.Width(), dc.Height()
 	dc.SetColor(opts.BackgroundColor)
 	dc.Clear()
 	dc.SetRGB(0, 0, 0)
-	grid := newSpatialHashMap(float64(opts.Width), float64(opts.Height), opts.Height/10)
+	grid := newSpatialHashMap(float64(width), float64(height), height/10)
 
 	for _, b := range opts.Mask {
 		if opts.Debug {
@@ -188,28 +285,32 @@ func NewWordcloud(sortedWordList *WordDataCoArrays, options ...Option) *Wordclou
 	}
 
 	radius := 1.0
-	maxRadius := math.Sqrt(float64(opts.Width*opts.Width + opts.Height*opts.Height))
+	maxRadius := math.Sqrt(float64(width*width + height*height))
 	circles := make(map[float64]*circle)
 	radii := make([]float64, 0)
 	for radius < maxRadius {
-		circles[radius] = newCircle(float64(opts.Width/2), float64(opts.Height/2), radius, 512)
+		circles[radius] = newCircle(float64(width/2), float64(height/2), radius, 512)
 		radii = append(radii, radius)
 		radius = radius + 5.0
 	}
 
-	rand.Seed(time.Now().UnixNano())
+	r := opts.Rand
+	if r == nil {
+		r = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
 
 	return &Wordcloud{
 		sortedWordList:  sortedWordList,
 		grid:            grid,
 		dc:              dc,
 		randomPlacement: opts.RandomPlacement,
-		width:           float64(opts.Width),
-		height:          float64(opts.Height),
+		width:           float64(width),
+		height:          float64(height),
 		opts:            opts,
 		circles:         circles,
 		fonts:           make(map[float64]font.Face),
 		radii:           radii,
+		rand:            r,
 	}
 }
 func (w *Wordcloud) SetHook(hook HookFunc) {
@@ -243,7 +344,7 @@ func (w *Wordcloud) setFont(size float64) {
 
 	if !ok {
 		var err error
-		f, err = gg.LoadFontFace(w.opts.FontFile, size)
+		f, err = w.dc.LoadFontFace(w.opts.FontFile, size)
 		if err != nil {
 			panic(err)
 		}
@@ -254,10 +355,10 @@ func (w *Wordcloud) setFont(size float64) {
 		}
 	}
 
-	w.dc.SetFontFace(f)
+	w.dc.SetFontFace(f, size)
 }
 
-func (w *Wordcloud) Place(idx int) bool {
+func (w *Wordcloud) Place(ctx context.Context, idx int) (bool, error) {
 	data := w.sortedWordList
 	word := data.Word[idx]
 
@@ -270,11 +371,14 @@ func (w *Wordcloud) Place(idx int) bool {
 
 	width += 5
 	height += 5
-	x, y, space := w.nextPos(width, height)
+	x, y, space, err := w.nextPos(ctx, width, height)
+	if err != nil {
+		return false, err
+	}
 	if !space {
 		data.Pos[idx] = notPlacedPos
 		data.Rect[idx] = 0.0
-		return false
+		return false, nil
 	}
 	const ax = 0.5
 	const ay = 0.5
@@ -300,34 +404,98 @@ func (w *Wordcloud) Place(idx int) bool {
 	} else {
 		w.grid.Add(box)
 	}
-	return true
+	return true, nil
 }
 
-// Draw tries to place words one by one, starting with the ones with the highest counts
+// Draw tries to place words one by one, starting with the ones with the highest counts.
+// It never stops early on its own; use DrawContext to bound the render with a context.
 func (w *Wordcloud) Draw() image.Image {
-	consecutiveMisses := 0
+	img, _ := w.DrawContext(context.Background())
+	return img
+}
+
+// DrawContext behaves like Draw but aborts as soon as ctx is canceled or its
+// deadline passes, so a caller can bound total render time or run a Wordcloud
+// inside a request that may be aborted. It returns whatever was rendered so
+// far alongside ctx.Err().
+//
+// When Options.Parallelism is greater than 1, the Options.SequentialHeadCount
+// largest words (which dominate the canvas and bias everything placed after
+// them) are still placed one at a time, and the remaining tail is placed
+// concurrently; see drawConcurrent.
+func (w *Wordcloud) DrawContext(ctx context.Context) (image.Image, error) {
 	l := w.sortedWordList.Length()
-	for i := 0; i < l; i++ {
-		success := w.Place(i)
+	if w.opts.Parallelism <= 1 || l == 0 {
+		return w.drawSequential(ctx, 0, l)
+	}
+
+	head := w.opts.SequentialHeadCount
+	if head < 0 {
+		head = 0
+	}
+	if head > l {
+		head = l
+	}
+	img, err := w.drawSequential(ctx, 0, head)
+	if err != nil || head >= l {
+		return img, err
+	}
+	if err := w.drawConcurrent(ctx, head, l); err != nil {
+		return w.dc.Image(), err
+	}
+	return w.dc.Image(), nil
+}
+
+// drawSequential places words [start,end) one by one, starting with the ones
+// with the highest counts.
+func (w *Wordcloud) drawSequential(ctx context.Context, start int, end int) (image.Image, error) {
+	consecutiveMisses := 0
+	for i := start; i < end; i++ {
+		if err := ctx.Err(); err != nil {
+			return w.dc.Image(), err
+		}
+		success, err := w.Place(ctx, i)
+		if err != nil {
+			return w.dc.Image(), err
+		}
 		if !success {
 			consecutiveMisses++
 			if consecutiveMisses > 10 {
-				return w.dc.Image()
+				return w.dc.Image(), nil
 			}
 			continue
 		}
 		consecutiveMisses = 0
 	}
-	return w.dc.Image()
+	return w.dc.Image(), nil
+}
+
+// randIntn draws from w.rand, which may be shared by concurrent placement
+// workers (see drawConcurrent), so access is serialized here.
+func (w *Wordcloud) randIntn(n int) int {
+	w.randMu.Lock()
+	defer w.randMu.Unlock()
+	return w.rand.Intn(n)
 }
 
-func (w *Wordcloud) nextRandom(width float64, height float64) (x float64, y float64, space bool) {
+func (w *Wordcloud) nextRandom(ctx context.Context, width float64, height float64) (x float64, y float64, space bool, err error) {
 	tries := 0
+	defer func() {
+		w.stats.RandomCalls.Add(1)
+		w.stats.RandomTries.Add(int64(tries))
+	}()
 	searching := true
 	var box Box
 	for searching && tries < 5000000 {
 		tries++
-		x, y = float64(rand.Intn(w.dc.Width())), float64(rand.Intn(w.dc.Height()))
+		if tries&0x3ff == 0 {
+			select {
+			case <-ctx.Done():
+				return 0, 0, false, ctx.Err()
+			default:
+			}
+		}
+		x, y = float64(w.randIntn(w.dc.Width())), float64(w.randIntn(w.dc.Height()))
 		// Is that position available?
 		box.Top = y + height/2
 		box.Left = x - width/2
@@ -367,9 +535,13 @@ type res struct {
 }
 
 // Multithreaded word placement
-func (w *Wordcloud) nextPos(width float64, height float64) (x float64, y float64, space bool) {
+func (w *Wordcloud) nextPos(ctx context.Context, width float64, height float64) (x float64, y float64, space bool, err error) {
+	start := time.Now()
+	defer func() {
+		w.stats.recordNextPos(time.Since(start))
+	}()
 	if w.randomPlacement {
-		return w.nextRandom(width, height)
+		return w.nextRandom(ctx, width, height)
 	}
 
 	space = false
@@ -398,7 +570,7 @@ func (w *Wordcloud) nextPos(width float64, height float64) (x float64, y float64
 						return
 					}
 					// Test the positions and post results on aggCh
-					aggCh <- w.testRadius(d.radius, d.positions, d.width, d.height)
+					aggCh <- w.testRadius(ctx, d.radius, d.positions, d.width, d.height)
 				case <-ch:
 					// Stop signal
 					return
@@ -417,6 +589,10 @@ func (w *Wordcloud) nextPos(width float64, height float64) (x float64, y float64
 				// Stop sending data immediately if a position has already been found
 				close(workCh)
 				return
+			case <-ctx.Done():
+				// Caller gave up; stop feeding workers
+				close(workCh)
+				return
 			case workCh <- workerData{
 				radius:    r,
 				positions: c.positions(),
@@ -453,40 +629,54 @@ func (w *Wordcloud) nextPos(width float64, height float64) (x float64, y float64
 	}()
 
 	// Finally, aggregate the results coming from workers
-	for d := range aggCh {
-		results[d.radius] = d
-		done[d.radius] = true
-		//check if we need to continue
-		failed := true
-		// Example: if we know that there's a successful placement at r=10 but have not received results for r=5,
-		// we need to wait as there might be a closer successful position
-		for _, r := range w.radii {
-			if !done[r] {
-				// Some positions are not done. They might be successful
-				failed = false
-				break
+	for {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case d, ok := <-aggCh:
+			if !ok {
+				return
 			}
-			// We have the successful placement with the lowest radius
-			if !results[r].failed {
-				return results[r].x, results[r].y, true
+			results[d.radius] = d
+			done[d.radius] = true
+			//check if we need to continue
+			failed := true
+			// Example: if we know that there's a successful placement at r=10 but have not received results for r=5,
+			// we need to wait as there might be a closer successful position
+			for _, r := range w.radii {
+				if !done[r] {
+					// Some positions are not done. They might be successful
+					failed = false
+					break
+				}
+				// We have the successful placement with the lowest radius
+				if !results[r].failed {
+					return results[r].x, results[r].y, true, nil
+				}
 			}
-		}
 
-		// We tried it all but could not place the word
-		if failed {
-			return
+			// We tried it all but could not place the word
+			if failed {
+				return
+			}
 		}
-
 	}
-	return
 }
 
 // test a series of points on a circle and returns as soon as there's a match
-func (w *Wordcloud) testRadius(radius float64, points []point, width float64, height float64) res {
+func (w *Wordcloud) testRadius(ctx context.Context, radius float64, points []point, width float64, height float64) res {
 	var box Box
 	var x, y float64
 
-	for _, p := range points {
+	for i, p := range points {
+		if i&0xff == 0 {
+			select {
+			case <-ctx.Done():
+				return res{radius: radius, failed: true}
+			default:
+			}
+		}
 		y = p.y
 		x = p.x
 
@@ -0,0 +1,214 @@
+package wordclouds
+
+import (
+	"context"
+	"image/color"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testFontFile = "testdata/Go-Regular.ttf"
+
+func testCorpus(n int) *WordDataCoArrays {
+	data := NewWordDataCoArrays(n)
+	for i := 0; i < n; i++ {
+		data.Add("word", n-i, uint16(i%8))
+	}
+	data.SortByCount()
+	return data
+}
+
+func TestInjectedRandIsReproducible(t *testing.T) {
+	opts := func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.Rand = rand.New(rand.NewSource(42))
+	}
+
+	data1 := testCorpus(40)
+	wc1 := NewWordcloud(data1, opts)
+	_, err := wc1.DrawContext(context.Background())
+	require.NoError(t, err)
+
+	opts2 := func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.Rand = rand.New(rand.NewSource(42))
+	}
+	data2 := testCorpus(40)
+	wc2 := NewWordcloud(data2, opts2)
+	_, err = wc2.DrawContext(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, data1.Pos, data2.Pos)
+	require.Equal(t, data1.Rect, data2.Rect)
+}
+
+// TestRandomPlacementIsReproducible exercises RandomPlacement, the one mode
+// that actually reads w.rand (see nextRandom); unlike the default circular
+// search, which never consults rand, so this is the path chunk0-3's Rand
+// injection exists for.
+func TestRandomPlacementIsReproducible(t *testing.T) {
+	opts := func(o *Options) {
+		o.Width = 256
+		o.Height = 256
+		o.FontFile = testFontFile
+		o.FontMaxSize = 24
+		o.FontMinSize = 8
+		o.RandomPlacement = true
+		o.Rand = rand.New(rand.NewSource(99))
+	}
+
+	data1 := testCorpus(10)
+	wc1 := NewWordcloud(data1, opts)
+	_, err := wc1.DrawContext(context.Background())
+	require.NoError(t, err)
+
+	opts2 := func(o *Options) {
+		o.Width = 256
+		o.Height = 256
+		o.FontFile = testFontFile
+		o.FontMaxSize = 24
+		o.FontMinSize = 8
+		o.RandomPlacement = true
+		o.Rand = rand.New(rand.NewSource(99))
+	}
+	data2 := testCorpus(10)
+	wc2 := NewWordcloud(data2, opts2)
+	_, err = wc2.DrawContext(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, data1.Pos, data2.Pos)
+	require.Equal(t, data1.Rect, data2.Rect)
+}
+
+// TestConcurrentPlacementRace exercises drawConcurrent's sharded-grid path;
+// run with -race to catch any concurrent access to a shared *spatialHashMap.
+func TestConcurrentPlacementRace(t *testing.T) {
+	data := testCorpus(80)
+	wc := NewWordcloud(data, func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.Rand = rand.New(rand.NewSource(7))
+		o.Parallelism = 4
+		o.SequentialHeadCount = 8
+	})
+
+	_, err := wc.DrawContext(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, data.PlacedCount(), 0)
+}
+
+// TestConcurrentPlacementMergesGridAndDrawsDebugBoxes guards two properties
+// of the concurrent tail that drawSequential gets for free: the boxes the
+// tail commits to its stripedGrid shards must end up in w.grid too (so code
+// reading w.grid after DrawContext returns sees every placed word, not just
+// the sequential head), and Options.Debug must outline the tail's boxes the
+// same way it outlines the sequential head's.
+func TestConcurrentPlacementMergesGridAndDrawsDebugBoxes(t *testing.T) {
+	data := testCorpus(80)
+	svgCtx := NewSVGDrawingContext(512, 512)
+	wc := NewWordcloud(data, func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.DrawingContext = svgCtx
+		o.Rand = rand.New(rand.NewSource(7))
+		o.Parallelism = 4
+		o.SequentialHeadCount = 8
+		o.Debug = true
+	})
+
+	_, err := wc.DrawContext(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, data.PlacedCount(), 0)
+
+	require.Equal(t, data.PlacedCount(), len(wc.grid.Boxes()),
+		"w.grid should hold every placed word, including the concurrent tail")
+
+	out := svgCtx.SVG()
+	require.Greater(t, strings.Count(out, "<rect x="), data.PlacedCount(),
+		"each placed word should get a debug box, same as the sequential path")
+}
+
+// TestParallelismAloneKeepsDefaultSequentialHead asserts that setting only
+// Options.Parallelism -- without also setting SequentialHeadCount -- still
+// places the largest words sequentially, instead of silently dropping the
+// whole corpus onto the concurrent tail path.
+func TestParallelismAloneKeepsDefaultSequentialHead(t *testing.T) {
+	data := testCorpus(80)
+	wc := NewWordcloud(data, func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.Rand = rand.New(rand.NewSource(7))
+		o.Parallelism = 4
+	})
+
+	require.Greater(t, wc.opts.SequentialHeadCount, 0)
+
+	_, err := wc.DrawContext(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, data.PlacedCount(), 0)
+}
+
+// TestSVGDrawingContextEmitsTextAndRects exercises NewSVGDrawingContext end
+// to end through a real Wordcloud.DrawContext, asserting the emitted SVG
+// carries an anchored <text> element per placed word and a <rect> per debug
+// box, rather than relying on a reviewer to check SVG() output by hand.
+func TestSVGDrawingContextEmitsTextAndRects(t *testing.T) {
+	data := testCorpus(5)
+	svgCtx := NewSVGDrawingContext(512, 512)
+	wc := NewWordcloud(data, func(o *Options) {
+		o.Width = 512
+		o.Height = 512
+		o.FontFile = testFontFile
+		o.DrawingContext = svgCtx
+		o.Debug = true
+	})
+
+	_, err := wc.DrawContext(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, data.PlacedCount(), 0)
+
+	out := svgCtx.SVG()
+	require.Contains(t, out, "<svg xmlns=\"http://www.w3.org/2000/svg\"")
+	require.Contains(t, out, "<text x=")
+	require.Contains(t, out, ">word</text>")
+	require.Contains(t, out, "<rect x=")
+}
+
+// TestColorToHexUnpremultipliesTranslucentColors guards colorToHex/
+// colorOpacityAttr against double-darkening a translucent color: the hex
+// must be the straight RGB (not RGBA()'s alpha-premultiplied one), with
+// alpha carried separately in a fill-opacity/stroke-opacity attribute.
+func TestColorToHexUnpremultipliesTranslucentColors(t *testing.T) {
+	translucentRed := color.NRGBA{R: 255, G: 0, B: 0, A: 128}
+	require.Equal(t, "#ff0000", colorToHex(translucentRed))
+	require.Equal(t, ` fill-opacity="0.502"`, colorOpacityAttr("fill-opacity", translucentRed))
+
+	require.Equal(t, "#000000", colorToHex(color.Black))
+	require.Equal(t, "", colorOpacityAttr("fill-opacity", color.Black))
+}
+
+// TestNewWordcloudPanicsOnDrawingContextSizeMismatch guards against the grid,
+// circle search and box.fits bounds being built from Options.Width/Height
+// while placement clamps to a differently-sized supplied DrawingContext,
+// which otherwise silently places every word off-canvas.
+func TestNewWordcloudPanicsOnDrawingContextSizeMismatch(t *testing.T) {
+	data := testCorpus(5)
+	require.Panics(t, func() {
+		NewWordcloud(data, func(o *Options) {
+			o.Width = 2048
+			o.Height = 1024
+			o.FontFile = testFontFile
+			o.DrawingContext = NewRasterDrawingContext(200, 200)
+		})
+	})
+}